@@ -11,11 +11,13 @@ package messagebird
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
 	"runtime"
@@ -39,12 +41,39 @@ var (
 	ErrUnexpectedResponse = errors.New("The MessageBird API is currently unavailable")
 )
 
+// Doer is the interface Client uses to perform the underlying HTTP round
+// trip. *http.Client satisfies it, but so do tracing clients, mocking
+// transports in tests, or circuit breakers.
+type Doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// RoundTripFunc is a single link in a Client's middleware chain. It
+// receives the outgoing request and next, the continuation that performs
+// the rest of the chain (eventually the Doer itself), so it can inspect or
+// modify the request before calling next, and the response/error after.
+type RoundTripFunc func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error)
+
 // Client is used to access API with a given key.
 // Uses standard lib HTTP client internally, so should be reused instead of created as needed and it is safe for concurrent use.
 type Client struct {
-	AccessKey  string       // The API access key
-	HTTPClient *http.Client // The HTTP client to send requests on
-	DebugLog   *log.Logger  // Optional logger for debugging purposes
+	AccessKey  string // The API access key
+	HTTPClient Doer   // The HTTP transport to send requests on
+	Logger     *slog.Logger
+
+	// MaxRetries is how many times a GET, DELETE or PUT request is retried
+	// on a network error, HTTP 429, or a 5xx response. Zero disables
+	// automatic retries.
+	MaxRetries int
+	// RetryBaseDelay is the backoff delay before the first retry.
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the backoff delay regardless of retry count.
+	RetryMaxDelay time.Duration
+	// RetryStatusCodes lists the HTTP status codes that trigger a retry, in
+	// addition to network errors.
+	RetryStatusCodes []int
+
+	middlewares []RoundTripFunc
 }
 
 // New creates a new MessageBird client object.
@@ -54,30 +83,238 @@ func New(accessKey string) *Client {
 		HTTPClient: &http.Client{
 			Timeout: httpClientTimeout,
 		},
+		MaxRetries:       defaultMaxRetries,
+		RetryBaseDelay:   defaultRetryBaseDelay,
+		RetryMaxDelay:    defaultRetryMaxDelay,
+		RetryStatusCodes: defaultRetryStatusCodes(),
+	}
+}
+
+// Use registers middlewares to run, in order, around every request made
+// through this Client. Middlewares added first run outermost, so the first
+// one registered sees the request before any of the others and the
+// response after all of them.
+func (c *Client) Use(middlewares ...RoundTripFunc) {
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// roundTrip sends request through the registered middleware chain and on
+// to the Doer.
+func (c *Client) roundTrip(request *http.Request) (*http.Response, error) {
+	next := c.HTTPClient.Do
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		middleware := c.middlewares[i]
+		prevNext := next
+		next = func(r *http.Request) (*http.Response, error) {
+			return middleware(r, prevNext)
+		}
+	}
+	return next(request)
+}
+
+// requestOptions carries the per-call overrides collected from the Option
+// values passed into RequestWithContext.
+type requestOptions struct {
+	headers      http.Header
+	endpoint     string
+	timeout      time.Duration
+	disableRetry bool
+	maxRetries   *int
+}
+
+// Option customizes a single RequestWithContext call, without
+// touching the Client's defaults. Options are applied in order, so a later
+// option wins over an earlier one.
+type Option func(*requestOptions)
+
+// WithHeader sets an additional HTTP header on the outgoing request, e.g. an
+// Idempotency-Key. It takes precedence over any header of the same name set
+// internally.
+func WithHeader(key, value string) Option {
+	return func(o *requestOptions) {
+		if o.headers == nil {
+			o.headers = make(http.Header)
+		}
+		o.headers.Set(key, value)
+	}
+}
+
+// WithEndpoint overrides the base endpoint for a single call, which is
+// useful for routing a request to a regional MessageBird endpoint.
+func WithEndpoint(endpoint string) Option {
+	return func(o *requestOptions) {
+		o.endpoint = endpoint
+	}
+}
+
+// WithTimeout bounds a single call with its own timeout, independent of any
+// deadline already carried by ctx. The call still fails early if ctx is
+// canceled first.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithoutRetry disables automatic retries for a single call, regardless of
+// the Client's MaxRetries setting.
+func WithoutRetry() Option {
+	return func(o *requestOptions) {
+		o.disableRetry = true
+	}
+}
+
+// WithMaxRetries overrides the Client's MaxRetries for a single call.
+func WithMaxRetries(maxRetries int) Option {
+	return func(o *requestOptions) {
+		o.maxRetries = &maxRetries
 	}
 }
 
 // Request is for internal use only and unstable.
 func (c *Client) Request(v interface{}, method, path string, data interface{}) error {
+	_, err := c.RequestWithContext(context.Background(), v, method, path, data)
+	return err
+}
+
+// RequestWithContext is for internal use only and unstable.
+// It behaves like Request, but threads ctx through to the underlying HTTP
+// call so callers can cancel it or attach a deadline, it accepts Options
+// for per-call overrides such as extra headers or an alternate endpoint,
+// and on success it returns a Response carrying the rate limit MessageBird
+// reported for the call. On error the returned Response is nil.
+func (c *Client) RequestWithContext(ctx context.Context, v interface{}, method, path string, data interface{}, options ...Option) (*Response, error) {
+	var opts requestOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	endpoint := Endpoint
+	if opts.endpoint != "" {
+		endpoint = opts.endpoint
+	}
+
 	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
-		path = fmt.Sprintf("%s/%s", Endpoint, path)
+		path = fmt.Sprintf("%s/%s", endpoint, path)
 	}
 	uri, err := url.Parse(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var jsonEncoded []byte
 	if data != nil {
 		jsonEncoded, err = json.Marshal(data)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	request, err := http.NewRequest(method, uri.String(), bytes.NewBuffer(jsonEncoded))
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+
+	maxRetries := c.MaxRetries
+	if opts.disableRetry {
+		maxRetries = 0
+	} else if opts.maxRetries != nil {
+		maxRetries = *opts.maxRetries
+	}
+
+	baseDelay := c.RetryBaseDelay
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+	maxDelay := c.RetryMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	statusCodes := c.RetryStatusCodes
+	if statusCodes == nil {
+		statusCodes = defaultRetryStatusCodes()
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, method, uri.String(), jsonEncoded, opts, v)
+		if err == nil {
+			return resp, nil
+		}
+
+		retryable := retryableMethods[method] && attempt < maxRetries
+		var rateLimited *RateLimitedError
+		switch {
+		case errors.As(err, &rateLimited):
+			if !retryable || !isRetryableStatus(statusCodes, http.StatusTooManyRequests) {
+				return nil, err
+			}
+			if err := sleepOrDone(ctx, rateLimited.RetryAfter); err != nil {
+				return nil, err
+			}
+		case retryable && isTransientRequestError(err):
+			if err := sleepOrDone(ctx, backoffDelay(attempt, baseDelay, maxDelay)); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, err
+		}
+	}
+}
+
+// isTransientRequestError reports whether err represents a failure worth
+// retrying: a network-level error, or a response status configured in
+// RetryStatusCodes (surfaced as *retryableStatusError, which also carries a
+// 500 response so that case stays governed by the same config).
+func isTransientRequestError(err error) bool {
+	var statusErr *retryableStatusError
+	if errors.As(err, &statusErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryableStatusError wraps a non-2xx response whose status code is
+// configured as retryable (e.g. a 502 with an HTML body, or a 500 when 500
+// is present in RetryStatusCodes). Err, if set, preserves the identity of
+// the error that would otherwise have been returned (e.g. ErrUnexpectedResponse),
+// so errors.Is still works once retries are exhausted.
+type retryableStatusError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *retryableStatusError) Unwrap() error { return e.Err }
+
+func (e *retryableStatusError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("messagebird: received retryable status %d", e.StatusCode)
+}
+
+func sleepOrDone(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// do performs a single HTTP round trip and decodes the result into v. It is
+// the non-retrying core of RequestWithContext.
+func (c *Client) do(ctx context.Context, method, uri string, jsonEncoded []byte, opts requestOptions, v interface{}) (*Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, uri, bytes.NewBuffer(jsonEncoded))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	request.Header.Set("Content-Type", "application/json")
@@ -85,49 +322,74 @@ func (c *Client) Request(v interface{}, method, path string, data interface{}) e
 	request.Header.Set("Authorization", "AccessKey "+c.AccessKey)
 	request.Header.Set("User-Agent", "MessageBird/ApiClient/"+ClientVersion+" Go/"+runtime.Version())
 
-	if c.DebugLog != nil {
-		if data != nil {
-			c.DebugLog.Printf("HTTP REQUEST: %s %s %s", method, uri.String(), jsonEncoded)
-		} else {
-			c.DebugLog.Printf("HTTP REQUEST: %s %s", method, uri.String())
-		}
+	for key := range opts.headers {
+		request.Header.Set(key, opts.headers.Get(key))
 	}
 
-	response, err := c.HTTPClient.Do(request)
+	if c.Logger != nil {
+		c.Logger.Debug("messagebird: HTTP request", "method", method, "url", uri, "body", string(jsonEncoded))
+	}
+
+	response, err := c.roundTrip(request)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer response.Body.Close()
 
 	responseBody, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if c.DebugLog != nil {
-		c.DebugLog.Printf("HTTP RESPONSE: %s", string(responseBody))
+	if c.Logger != nil {
+		c.Logger.Debug("messagebird: HTTP response", "status", response.StatusCode, "body", string(responseBody))
+	}
+
+	resp := &Response{RateLimit: parseRateLimit(response.Header)}
+
+	statusCodes := c.RetryStatusCodes
+	if statusCodes == nil {
+		statusCodes = defaultRetryStatusCodes()
+	}
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		retryAfter, ok := parseRetryAfter(response.Header, time.Now())
+		if !ok {
+			retryAfter = time.Until(resp.RateLimit.Reset)
+		}
+		var errorResponse ErrorResponse
+		json.Unmarshal(responseBody, &errorResponse) //nolint:errcheck
+		return resp, &RateLimitedError{RateLimit: resp.RateLimit, RetryAfter: retryAfter, ErrorResponse: errorResponse}
 	}
 
 	// Status code 500 is a server error and means nothing can be done at this
-	// point.
+	// point. Whether it is retried is governed by RetryStatusCodes like any
+	// other status, rather than unconditionally.
 	if response.StatusCode == 500 {
-		return ErrUnexpectedResponse
+		if isRetryableStatus(statusCodes, 500) {
+			return resp, &retryableStatusError{StatusCode: 500, Err: ErrUnexpectedResponse}
+		}
+		return resp, ErrUnexpectedResponse
 	}
 	// Status codes 200 and 201 are indicative of being able to convert the
 	// response body to the struct that was specified.
 	if response.StatusCode == 200 || response.StatusCode == 201 {
 		if err := json.Unmarshal(responseBody, &v); err != nil {
-			return fmt.Errorf("could not decode response JSON, %s: %v", string(responseBody), err)
+			return resp, fmt.Errorf("could not decode response JSON, %s: %v", string(responseBody), err)
 		}
-		return nil
+		return resp, nil
+	}
+
+	if isRetryableStatus(statusCodes, response.StatusCode) {
+		return resp, &retryableStatusError{StatusCode: response.StatusCode}
 	}
 
 	// Anything else than a 200/201/500 should be a JSON error.
 	var errorResponse ErrorResponse
 	if err := json.Unmarshal(responseBody, &errorResponse); err != nil {
-		return err
+		return resp, err
 	}
 
-	return errorResponse
+	return resp, errorResponse
 }
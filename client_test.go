@@ -0,0 +1,95 @@
+package messagebird
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestWithContextRespectsCanceledContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("server should not have been called with an already-canceled context")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := New("test-key")
+	var v map[string]interface{}
+	_, err := client.RequestWithContext(ctx, &v, http.MethodGet, server.URL, nil)
+	if err == nil {
+		t.Fatal("expected an error for a canceled context")
+	}
+}
+
+func TestWithHeaderOverridesInternalHeaders(t *testing.T) {
+	var gotUserAgent, gotIdempotencyKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.Header.Get("User-Agent")
+		gotIdempotencyKey = r.Header.Get("Idempotency-Key")
+		w.Write([]byte(`{}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client := New("test-key")
+	var v map[string]interface{}
+	_, err := client.RequestWithContext(
+		context.Background(), &v, http.MethodGet, server.URL, nil,
+		WithHeader("Idempotency-Key", "abc123"),
+		WithHeader("User-Agent", "custom-agent"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotUserAgent != "custom-agent" {
+		t.Errorf("User-Agent = %q, want %q", gotUserAgent, "custom-agent")
+	}
+	if gotIdempotencyKey != "abc123" {
+		t.Errorf("Idempotency-Key = %q, want %q", gotIdempotencyKey, "abc123")
+	}
+}
+
+func TestWithEndpointOverridesBaseEndpoint(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write([]byte(`{}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client := New("test-key")
+	var v map[string]interface{}
+	_, err := client.RequestWithContext(
+		context.Background(), &v, http.MethodGet, "messages", nil,
+		WithEndpoint(server.URL),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPath != "/messages" {
+		t.Errorf("path = %q, want %q", gotPath, "/messages")
+	}
+}
+
+func TestWithTimeoutCancelsSlowRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client := New("test-key")
+	client.MaxRetries = 0
+
+	var v map[string]interface{}
+	_, err := client.RequestWithContext(
+		context.Background(), &v, http.MethodGet, server.URL, nil,
+		WithTimeout(time.Millisecond),
+	)
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+}
@@ -0,0 +1,108 @@
+package messagebird
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Error represents a single error as returned by the MessageBird API, e.g.
+// as one of the entries in ErrorResponse.Errors.
+type Error struct {
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+	Parameter   string `json:"parameter"`
+}
+
+func (e *Error) Error() string {
+	str := e.Description
+	if e.Parameter != "" {
+		str = str + " (parameter: " + e.Parameter + ")"
+	}
+	return str
+}
+
+// Is reports whether target is a sentinel *Error with the same Code,
+// so callers can write errors.Is(err, messagebird.ErrNotFound).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code
+}
+
+// Sentinel error codes for the MessageBird API, as documented at
+// https://developers.messagebird.com/api/#error-codes. Compare against
+// them with errors.Is, e.g. errors.Is(err, messagebird.ErrNotFound).
+var (
+	ErrAuthFailed        = &Error{Code: 1, Description: "Authentication failed"}
+	ErrRequestNotAllowed = &Error{Code: 2, Description: "Request not allowed"}
+	ErrMissingParams     = &Error{Code: 9, Description: "Missing params"}
+	ErrInvalidParams     = &Error{Code: 10, Description: "Invalid params"}
+	ErrNotFound          = &Error{Code: 20, Description: "Not found"}
+	ErrTooManyRequests   = &Error{Code: 22, Description: "Too many requests"}
+)
+
+// ErrorResponse is returned whenever the MessageBird API responds with one
+// or more errors instead of the requested resource.
+type ErrorResponse struct {
+	Errors []Error
+}
+
+func (errorResponse ErrorResponse) Error() string {
+	errorStrings := make([]string, len(errorResponse.Errors))
+	for i := range errorResponse.Errors {
+		errorStrings[i] = errorResponse.Errors[i].Error()
+	}
+	return strings.Join(errorStrings, ", ")
+}
+
+// Is reports whether target is a sentinel *Error matching the Code of any
+// of the errors in errorResponse.Errors.
+func (errorResponse ErrorResponse) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	for i := range errorResponse.Errors {
+		if errorResponse.Errors[i].Code == t.Code {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap exposes the individual errors so callers can use errors.As to pull
+// out a specific entry, e.g. to read its Parameter.
+func (errorResponse ErrorResponse) Unwrap() []error {
+	errs := make([]error, len(errorResponse.Errors))
+	for i := range errorResponse.Errors {
+		errs[i] = &errorResponse.Errors[i]
+	}
+	return errs
+}
+
+// IsAuthenticationError reports whether err is (or wraps) an authentication
+// failure, i.e. an invalid or missing access key.
+func IsAuthenticationError(err error) bool {
+	return errors.Is(err, ErrAuthFailed)
+}
+
+// IsNotFound reports whether err is (or wraps) a "not found" error.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsRateLimited reports whether err is (or wraps) a rate-limit error, and if
+// so, how long the caller should wait before retrying.
+func IsRateLimited(err error) (time.Duration, bool) {
+	var rateLimited *RateLimitedError
+	if errors.As(err, &rateLimited) {
+		return rateLimited.RetryAfter, true
+	}
+	if errors.Is(err, ErrTooManyRequests) {
+		return 0, true
+	}
+	return 0, false
+}
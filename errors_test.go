@@ -0,0 +1,52 @@
+package messagebird
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSentinelErrorsAreDistinctAndComparable(t *testing.T) {
+	sentinels := []*Error{
+		ErrAuthFailed,
+		ErrRequestNotAllowed,
+		ErrMissingParams,
+		ErrInvalidParams,
+		ErrNotFound,
+		ErrTooManyRequests,
+	}
+
+	seen := make(map[int]*Error)
+	for _, s := range sentinels {
+		if other, ok := seen[s.Code]; ok {
+			t.Fatalf("sentinels %q and %q share code %d", other.Description, s.Description, s.Code)
+		}
+		seen[s.Code] = s
+	}
+
+	for _, s := range sentinels {
+		resp := ErrorResponse{Errors: []Error{{Code: s.Code, Description: "some API error"}}}
+		if !errors.Is(resp, s) {
+			t.Errorf("errors.Is(resp, %s) = false, want true", s.Description)
+		}
+		for _, other := range sentinels {
+			if other.Code == s.Code {
+				continue
+			}
+			if errors.Is(resp, other) {
+				t.Errorf("errors.Is(resp, %s) = true for a response carrying code %d, want false", other.Description, s.Code)
+			}
+		}
+	}
+}
+
+func TestIsAuthenticationErrorDoesNotMatchUnrelatedCode(t *testing.T) {
+	// Code 2 (request-not-allowed) is a distinct condition from auth
+	// failure and must not be reported as an authentication error.
+	err := ErrorResponse{Errors: []Error{{Code: 2, Description: "insufficient balance to complete this request"}}}
+	if IsAuthenticationError(err) {
+		t.Error("IsAuthenticationError = true for a request-not-allowed error, want false")
+	}
+	if !errors.Is(err, ErrRequestNotAllowed) {
+		t.Error("errors.Is(err, ErrRequestNotAllowed) = false, want true")
+	}
+}
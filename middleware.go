@@ -0,0 +1,82 @@
+package messagebird
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// UserAgent returns a middleware that overrides the User-Agent header set
+// internally by Client, which is useful when running behind a proxy that
+// needs to identify the calling application rather than this library.
+func UserAgent(userAgent string) RoundTripFunc {
+	return func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+		req.Header.Set("User-Agent", userAgent)
+		return next(req)
+	}
+}
+
+// RequestID returns a middleware that tags every outgoing request that has
+// no X-Request-ID header yet with a random one, so the call can be
+// correlated with MessageBird support or with the caller's own logs. A
+// value already set on the request, e.g. via WithHeader, is left as is.
+func RequestID() RoundTripFunc {
+	return func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+		if req.Header.Get("X-Request-ID") == "" {
+			req.Header.Set("X-Request-ID", newRequestID())
+		}
+		return next(req)
+	}
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// MetricsRecorder is implemented by metrics backends (e.g. a Prometheus
+// HistogramVec wrapper) that Prometheus reports request outcomes to. It is
+// defined here rather than importing a metrics library directly, so this
+// package stays dependency-free.
+type MetricsRecorder interface {
+	ObserveRequest(method, path string, statusCode int, duration time.Duration)
+}
+
+// Prometheus returns a middleware that reports the method, path, status
+// code and duration of every request to recorder.
+func Prometheus(recorder MetricsRecorder) RoundTripFunc {
+	return func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next(req)
+		statusCode := 0
+		if resp != nil {
+			statusCode = resp.StatusCode
+		}
+		recorder.ObserveRequest(req.Method, req.URL.Path, statusCode, time.Since(start))
+		return resp, err
+	}
+}
+
+// Logging returns a middleware that logs every outgoing request and its
+// outcome to logger at debug level. Unlike Client.Logger, which logs the
+// request and response without timing, this also reports request duration.
+func Logging(logger *slog.Logger) RoundTripFunc {
+	return func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next(req)
+		attrs := []any{"method", req.Method, "url", req.URL.String(), "duration", time.Since(start)}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+			logger.Debug("messagebird: request failed", attrs...)
+			return resp, err
+		}
+		attrs = append(attrs, "status", resp.StatusCode)
+		logger.Debug("messagebird: request completed", attrs...)
+		return resp, nil
+	}
+}
@@ -0,0 +1,108 @@
+package messagebird
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type recorderFunc func(method, path string, statusCode int, duration time.Duration)
+
+func (f recorderFunc) ObserveRequest(method, path string, statusCode int, duration time.Duration) {
+	f(method, path, statusCode, duration)
+}
+
+func TestMiddlewaresRunInRegistrationOrderAroundTheRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	var order []string
+	trace := func(name string) RoundTripFunc {
+		return func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+			order = append(order, name+":before")
+			resp, err := next(req)
+			order = append(order, name+":after")
+			return resp, err
+		}
+	}
+
+	client := New("test-key")
+	client.Use(trace("outer"), trace("inner"))
+
+	var v map[string]interface{}
+	if _, err := client.RequestWithContext(context.Background(), &v, http.MethodGet, server.URL, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"outer:before", "inner:before", "inner:after", "outer:after"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestRequestIDMiddlewareSetsHeaderOnlyWhenAbsent(t *testing.T) {
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Request-ID")
+		w.Write([]byte(`{}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client := New("test-key")
+	client.Use(RequestID())
+
+	var v map[string]interface{}
+	if _, err := client.RequestWithContext(
+		context.Background(), &v, http.MethodGet, server.URL, nil,
+		WithHeader("X-Request-ID", "caller-supplied"),
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotHeader != "caller-supplied" {
+		t.Errorf("X-Request-ID = %q, want the caller-supplied value to be preserved", gotHeader)
+	}
+
+	if _, err := client.RequestWithContext(context.Background(), &v, http.MethodGet, server.URL, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotHeader == "" {
+		t.Error("X-Request-ID is empty, want a generated value when the caller supplies none")
+	}
+}
+
+func TestPrometheusMiddlewareReportsOutcome(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	var gotMethod, gotPath string
+	var gotStatus int
+	recorder := recorderFunc(func(method, path string, statusCode int, _ time.Duration) {
+		gotMethod, gotPath, gotStatus = method, path, statusCode
+	})
+
+	client := New("test-key")
+	client.Use(Prometheus(recorder))
+
+	var v map[string]interface{}
+	if _, err := client.RequestWithContext(context.Background(), &v, http.MethodGet, server.URL+"/messages", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotMethod != http.MethodGet || gotPath != "/messages" || gotStatus != http.StatusCreated {
+		t.Errorf("got method=%q path=%q status=%d", gotMethod, gotPath, gotStatus)
+	}
+}
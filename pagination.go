@@ -0,0 +1,169 @@
+package messagebird
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// PageInfo reports the pagination metadata MessageBird returns alongside a
+// page of list results.
+type PageInfo struct {
+	Offset     int
+	Limit      int
+	Count      int
+	TotalCount int
+}
+
+// ListOptions controls the first page fetched by List, and is carried over
+// unchanged (besides Offset, which the Paginator advances itself) on every
+// subsequent page.
+type ListOptions struct {
+	// Limit is the page size. Zero uses the API's default.
+	Limit int
+	// Offset is the index of the first item to return.
+	Offset int
+	// Filters are encoded into the query string as-is, e.g. {"status":
+	// "delivered"} becomes "?status=delivered".
+	Filters map[string]string
+}
+
+func (o ListOptions) queryString() string {
+	values := url.Values{}
+	if o.Limit > 0 {
+		values.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		values.Set("offset", strconv.Itoa(o.Offset))
+	}
+	for k, v := range o.Filters {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// listEnvelope is the shape MessageBird wraps a page of list results in.
+type listEnvelope[T any] struct {
+	Offset     int `json:"offset"`
+	Limit      int `json:"limit"`
+	Count      int `json:"count"`
+	TotalCount int `json:"totalCount"`
+	Items      []T `json:"items"`
+}
+
+// Paginator lazily fetches successive pages of a MessageBird list endpoint.
+// Create one with List, then either drive it with Next/Item/Err, or use
+// All or Range to consume every item.
+type Paginator[T any] struct {
+	client *Client
+	path   string
+	opts   ListOptions
+
+	items   []T
+	index   int
+	page    PageInfo
+	fetched bool
+	done    bool
+	err     error
+}
+
+// List returns a Paginator over the items at path, a MessageBird list
+// endpoint. No request is made until the Paginator is advanced.
+func List[T any](client *Client, path string, opts ListOptions) *Paginator[T] {
+	return &Paginator[T]{client: client, path: path, opts: opts}
+}
+
+// Next advances the Paginator to the next item, fetching another page if
+// needed. It returns false when there are no more items or an error
+// occurred, at which point Err reports which.
+func (p *Paginator[T]) Next(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+	if p.fetched && p.index+1 < len(p.items) {
+		p.index++
+		return true
+	}
+	if p.done {
+		return false
+	}
+	if err := p.fetchNextPage(ctx); err != nil {
+		p.err = err
+		return false
+	}
+	if len(p.items) == 0 {
+		p.done = true
+		return false
+	}
+	p.index = 0
+	return true
+}
+
+func (p *Paginator[T]) fetchNextPage(ctx context.Context) error {
+	query := p.opts.queryString()
+	path := p.path
+	if query != "" {
+		path = fmt.Sprintf("%s?%s", path, query)
+	}
+
+	var envelope listEnvelope[T]
+	if _, err := p.client.RequestWithContext(ctx, &envelope, "GET", path, nil); err != nil {
+		return err
+	}
+
+	p.items = envelope.Items
+	p.page = PageInfo{
+		Offset:     envelope.Offset,
+		Limit:      envelope.Limit,
+		Count:      envelope.Count,
+		TotalCount: envelope.TotalCount,
+	}
+	p.fetched = true
+
+	p.opts.Offset = envelope.Offset + len(envelope.Items)
+	if len(envelope.Items) == 0 || p.opts.Offset >= envelope.TotalCount {
+		p.done = true
+	}
+
+	return nil
+}
+
+// Item returns the item Next last advanced to. It must only be called
+// after a call to Next that returned true.
+func (p *Paginator[T]) Item() T {
+	return p.items[p.index]
+}
+
+// Err returns the error, if any, that stopped iteration.
+func (p *Paginator[T]) Err() error {
+	return p.err
+}
+
+// Page returns the metadata of the most recently fetched page.
+func (p *Paginator[T]) Page() PageInfo {
+	return p.page
+}
+
+// All consumes the Paginator and returns every remaining item.
+func (p *Paginator[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for p.Next(ctx) {
+		all = append(all, p.Item())
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// Range calls fn for every remaining item, stopping early if fn returns
+// false or the Paginator errors.
+func (p *Paginator[T]) Range(ctx context.Context, fn func(T) bool) error {
+	for p.Next(ctx) {
+		if !fn(p.Item()) {
+			return nil
+		}
+	}
+	return p.Err()
+}
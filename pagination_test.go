@@ -0,0 +1,56 @@
+package messagebird
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type stubItem struct {
+	ID string `json:"id"`
+}
+
+func TestPaginatorAllFetchesEveryPage(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		var items []stubItem
+		var nextOffset, total int
+		switch r.URL.Query().Get("offset") {
+		case "", "0":
+			items = []stubItem{{ID: "a"}, {ID: "b"}}
+			nextOffset, total = 0, 3
+		default:
+			items = []stubItem{{ID: "c"}}
+			nextOffset, total = 2, 3
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{ //nolint:errcheck
+			"offset":     nextOffset,
+			"limit":      2,
+			"count":      len(items),
+			"totalCount": total,
+			"items":      items,
+		})
+	}))
+	defer server.Close()
+
+	client := New("test-key")
+	client.MaxRetries = 0
+
+	paginator := List[stubItem](client, server.URL+"/items", ListOptions{Limit: 2})
+
+	all, err := paginator.All(context.Background())
+	if err != nil {
+		t.Fatalf("All returned error: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 items, got %d: %+v", len(all), all)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
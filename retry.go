@@ -0,0 +1,144 @@
+package messagebird
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultMaxRetries is how many times an idempotent request is retried
+	// before the last error is returned to the caller.
+	defaultMaxRetries = 3
+
+	// defaultRetryBaseDelay is the backoff delay before the first retry.
+	defaultRetryBaseDelay = 500 * time.Millisecond
+
+	// defaultRetryMaxDelay caps the backoff delay regardless of how many
+	// retries have already been attempted.
+	defaultRetryMaxDelay = 30 * time.Second
+)
+
+// retryableMethods lists the HTTP methods that are safe to retry
+// automatically, i.e. the ones MessageBird treats as idempotent.
+var retryableMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodDelete: true,
+	http.MethodPut:    true,
+}
+
+// defaultRetryStatusCodes returns the status codes that are retried by
+// default: 429 and the 5xx range.
+func defaultRetryStatusCodes() []int {
+	codes := []int{http.StatusTooManyRequests}
+	for status := 500; status <= 599; status++ {
+		codes = append(codes, status)
+	}
+	return codes
+}
+
+func isRetryableStatus(statusCodes []int, status int) bool {
+	for _, code := range statusCodes {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimit carries the rate limit information MessageBird reports on every
+// response via the X-RateLimit-* headers.
+type RateLimit struct {
+	Limit     int       // Maximum number of requests allowed in the current window.
+	Remaining int       // Number of requests left in the current window.
+	Reset     time.Time // When the current window resets.
+}
+
+// Response wraps the rate limit information returned alongside a decoded API
+// response.
+type Response struct {
+	RateLimit RateLimit
+}
+
+// RateLimitedError is returned when the MessageBird API rejects a request
+// with HTTP 429 and the client does not retry it: automatic retries are
+// disabled, MaxRetries is exhausted, the method is not idempotent, or 429
+// is not in RetryStatusCodes. ErrorResponse carries whatever error detail the API
+// sent in the response body, if any, so callers can still inspect it (and
+// errors.Is/errors.As still reach the individual error codes, e.g.
+// errors.Is(err, messagebird.ErrTooManyRequests)).
+type RateLimitedError struct {
+	RateLimit     RateLimit
+	RetryAfter    time.Duration
+	ErrorResponse ErrorResponse
+}
+
+func (e *RateLimitedError) Error() string {
+	if len(e.ErrorResponse.Errors) > 0 {
+		return fmt.Sprintf("messagebird: rate limited, retry after %s: %s", e.RetryAfter, e.ErrorResponse.Error())
+	}
+	return fmt.Sprintf("messagebird: rate limited, retry after %s", e.RetryAfter)
+}
+
+// Unwrap exposes the underlying ErrorResponse, if the API sent one, so
+// errors.Is/errors.As can reach the individual error codes.
+func (e *RateLimitedError) Unwrap() error {
+	if len(e.ErrorResponse.Errors) == 0 {
+		return nil
+	}
+	return e.ErrorResponse
+}
+
+// parseRateLimit reads the X-RateLimit-* headers into a RateLimit. Missing
+// or unparsable headers are left at their zero value.
+func parseRateLimit(header http.Header) RateLimit {
+	var rateLimit RateLimit
+	if v := header.Get("X-RateLimit-Limit"); v != "" {
+		rateLimit.Limit, _ = strconv.Atoi(v)
+	}
+	if v := header.Get("X-RateLimit-Remaining"); v != "" {
+		rateLimit.Remaining, _ = strconv.Atoi(v)
+	}
+	if v := header.Get("X-RateLimit-Reset"); v != "" {
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+			rateLimit.Reset = time.Unix(secs, 0)
+		}
+	}
+	return rateLimit
+}
+
+// parseRetryAfter reads the Retry-After header, which MessageBird may send
+// either as a number of seconds or as an HTTP-date.
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			secs = 0
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// backoffDelay computes a jittered exponential backoff delay for the given
+// retry attempt (0-indexed), using full jitter: a uniform random value
+// between 0 and the exponential delay, capped at max.
+func backoffDelay(attempt int, base, max time.Duration) time.Duration {
+	exp := float64(base) * math.Pow(2, float64(attempt))
+	if exp > float64(max) || exp <= 0 {
+		exp = float64(max)
+	}
+	return time.Duration(rand.Int63n(int64(exp) + 1))
+}
@@ -0,0 +1,158 @@
+package messagebird
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestWithContextRetriesRateLimitedRequestsAndPreservesBody(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"value"}` {
+			t.Errorf("request %d: body = %q, want %q", requests, body, `{"name":"value"}`)
+		}
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"errors":[{"code":22,"description":"Too many requests, slow down"}]}`)) //nolint:errcheck
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client := New("test-key")
+
+	var v map[string]interface{}
+	_, err := client.RequestWithContext(context.Background(), &v, http.MethodGet, server.URL, map[string]string{"name": "value"})
+	if err != nil {
+		t.Fatalf("expected the retry to succeed, got error: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestRateLimitedErrorCarriesAPIErrorDetail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"errors":[{"code":22,"description":"Too many requests, slow down"}]}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client := New("test-key")
+
+	var v map[string]interface{}
+	_, err := client.RequestWithContext(context.Background(), &v, http.MethodGet, server.URL, nil, WithMaxRetries(0))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !errors.Is(err, ErrTooManyRequests) {
+		t.Errorf("errors.Is(err, ErrTooManyRequests) = false, want true (err: %v)", err)
+	}
+
+	var rateLimited *RateLimitedError
+	if !errors.As(err, &rateLimited) {
+		t.Fatalf("expected a *RateLimitedError, got %T: %v", err, err)
+	}
+	if len(rateLimited.ErrorResponse.Errors) != 1 || rateLimited.ErrorResponse.Errors[0].Description != "Too many requests, slow down" {
+		t.Errorf("expected the API error detail to survive, got %+v", rateLimited.ErrorResponse)
+	}
+}
+
+func TestRequestWithContextRetriesServerErrors(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := New("test-key")
+	client.RetryBaseDelay = time.Millisecond
+	client.RetryMaxDelay = time.Millisecond
+
+	var v map[string]interface{}
+	_, err := client.RequestWithContext(context.Background(), &v, http.MethodGet, server.URL, nil)
+	if !errors.Is(err, ErrUnexpectedResponse) {
+		t.Fatalf("expected ErrUnexpectedResponse, got %v", err)
+	}
+	if requests != client.MaxRetries+1 {
+		t.Fatalf("expected %d requests, got %d", client.MaxRetries+1, requests)
+	}
+}
+
+func TestRequestWithContextHonorsEmptyRetryStatusCodes(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := New("test-key")
+	client.RetryStatusCodes = []int{}
+
+	var v map[string]interface{}
+	_, err := client.RequestWithContext(context.Background(), &v, http.MethodGet, server.URL, nil)
+	if !errors.Is(err, ErrUnexpectedResponse) {
+		t.Fatalf("expected ErrUnexpectedResponse, got %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected exactly 1 request when RetryStatusCodes is empty, got %d", requests)
+	}
+}
+
+func TestRequestWithContextHonorsEmptyRetryStatusCodesFor429(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"errors":[{"code":22,"description":"Too many requests, slow down"}]}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client := New("test-key")
+	client.RetryStatusCodes = []int{}
+
+	var v map[string]interface{}
+	_, err := client.RequestWithContext(context.Background(), &v, http.MethodGet, server.URL, nil)
+	var rateLimited *RateLimitedError
+	if !errors.As(err, &rateLimited) {
+		t.Fatalf("expected a *RateLimitedError, got %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected exactly 1 request when RetryStatusCodes is empty, got %d", requests)
+	}
+}
+
+func TestRequestWithContextDoesNotRetryNonIdempotentMethods(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := New("test-key")
+
+	var v map[string]interface{}
+	_, err := client.RequestWithContext(context.Background(), &v, http.MethodPost, server.URL, nil)
+	if !errors.Is(err, ErrUnexpectedResponse) {
+		t.Fatalf("expected ErrUnexpectedResponse, got %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected exactly 1 request for a POST, got %d", requests)
+	}
+}
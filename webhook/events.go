@@ -0,0 +1,95 @@
+// Package webhook helps receive and verify MessageBird's outbound webhooks
+// for inbound SMS, Voice and Conversation events.
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType identifies the kind of event a webhook payload carries. See
+// https://developers.messagebird.com/api/#event-types for the full list.
+type EventType string
+
+const (
+	EventMessageReceived            EventType = "message.received"
+	EventMessageUpdated             EventType = "message.updated"
+	EventVoiceCallEnded             EventType = "voice.call.ended"
+	EventConversationMessageCreated EventType = "conversation.message.created"
+)
+
+// Event is the envelope common to every MessageBird webhook delivery. The
+// type-specific fields live in Payload; use one of the Event.As* helpers
+// (or json.Unmarshal(event.Payload, &v) directly) to decode it.
+type Event struct {
+	ID              string          `json:"id"`
+	Type            EventType       `json:"type"`
+	CreatedDatetime time.Time       `json:"createdDatetime"`
+	Payload         json.RawMessage `json:"payload"`
+}
+
+// MessageReceivedPayload is the payload of a message.received event.
+type MessageReceivedPayload struct {
+	ID        string    `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdDatetime"`
+}
+
+// MessageUpdatedPayload is the payload of a message.updated event.
+type MessageUpdatedPayload struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	StatusAt  time.Time `json:"statusDatetime"`
+	Recipient string    `json:"recipient"`
+}
+
+// VoiceCallEndedPayload is the payload of a voice.call.ended event.
+type VoiceCallEndedPayload struct {
+	ID          string    `json:"id"`
+	Source      string    `json:"source"`
+	Destination string    `json:"destination"`
+	Status      string    `json:"status"`
+	EndedAt     time.Time `json:"endedDatetime"`
+}
+
+// ConversationMessageCreatedPayload is the payload of a
+// conversation.message.created event.
+type ConversationMessageCreatedPayload struct {
+	ID             string `json:"id"`
+	ConversationID string `json:"conversationId"`
+	Type           string `json:"type"`
+	Content        struct {
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// AsMessageReceived decodes Payload as a MessageReceivedPayload.
+func (e Event) AsMessageReceived() (MessageReceivedPayload, error) {
+	var p MessageReceivedPayload
+	err := json.Unmarshal(e.Payload, &p)
+	return p, err
+}
+
+// AsMessageUpdated decodes Payload as a MessageUpdatedPayload.
+func (e Event) AsMessageUpdated() (MessageUpdatedPayload, error) {
+	var p MessageUpdatedPayload
+	err := json.Unmarshal(e.Payload, &p)
+	return p, err
+}
+
+// AsVoiceCallEnded decodes Payload as a VoiceCallEndedPayload.
+func (e Event) AsVoiceCallEnded() (VoiceCallEndedPayload, error) {
+	var p VoiceCallEndedPayload
+	err := json.Unmarshal(e.Payload, &p)
+	return p, err
+}
+
+// AsConversationMessageCreated decodes Payload as a
+// ConversationMessageCreatedPayload.
+func (e Event) AsConversationMessageCreated() (ConversationMessageCreatedPayload, error) {
+	var p ConversationMessageCreatedPayload
+	err := json.Unmarshal(e.Payload, &p)
+	return p, err
+}
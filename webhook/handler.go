@@ -0,0 +1,244 @@
+package webhook
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the HTTP header MessageBird sends the JWS signature in.
+const SignatureHeader = "MessageBird-Signature-JWS"
+
+// defaultClockSkew bounds how far a webhook's iat/nbf/exp claims may drift
+// from the server's clock before it is rejected.
+const defaultClockSkew = 5 * time.Minute
+
+var (
+	// ErrMissingSignature is returned when the request has no
+	// MessageBird-Signature-JWS header.
+	ErrMissingSignature = errors.New("webhook: missing " + SignatureHeader + " header")
+	// ErrInvalidSignature is returned when the JWS signature does not
+	// verify against the configured signing key.
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	// ErrExpired is returned when the token's claims are outside the
+	// allowed clock skew.
+	ErrExpired = errors.New("webhook: token is expired or not yet valid")
+	// ErrURLMismatch is returned when url_hash does not match the request URL.
+	ErrURLMismatch = errors.New("webhook: url_hash does not match request URL")
+	// ErrPayloadMismatch is returned when payload_hash does not match the body.
+	ErrPayloadMismatch = errors.New("webhook: payload_hash does not match request body")
+	// ErrReplayed is returned when the token's jti has already been seen
+	// within the configured clock skew window.
+	ErrReplayed = errors.New("webhook: token has already been processed")
+)
+
+// Option configures Handler. Verify takes no options and always uses the
+// default clock skew.
+type Option func(*config)
+
+type config struct {
+	clockSkew time.Duration
+	seenCache SeenCache
+}
+
+// WithClockSkew overrides the default allowed drift between the server's
+// clock and the iat/nbf/exp claims on the webhook token.
+func WithClockSkew(d time.Duration) Option {
+	return func(c *config) { c.clockSkew = d }
+}
+
+// WithSeenCache overrides the SeenCache used for replay protection. Pass
+// nil to disable replay protection entirely.
+func WithSeenCache(cache SeenCache) Option {
+	return func(c *config) { c.seenCache = cache }
+}
+
+// Handler returns an http.Handler that verifies the MessageBird-Signature-JWS
+// header on every request using signingKey, decodes the body as an Event,
+// and calls dispatch. It responds 401 if verification fails, 409 if the
+// token has already been processed, 500 if dispatch returns an error, and
+// 204 on success.
+func Handler(signingKey string, dispatch func(ctx context.Context, event Event) error, opts ...Option) http.Handler {
+	cfg := config{clockSkew: defaultClockSkew, seenCache: NewMemorySeenCache()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	key, keyErr := parsePublicKey(signingKey)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if keyErr != nil {
+			http.Error(w, "webhook: invalid signing key", http.StatusInternalServerError)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "webhook: could not read body", http.StatusBadRequest)
+			return
+		}
+
+		claims, err := verify(body, r.Header, requestURL(r), key, cfg.clockSkew)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		reserved := cfg.seenCache != nil && claims.JTI != ""
+		if reserved {
+			if cfg.seenCache.SeenRecently(claims.JTI, cfg.clockSkew) {
+				http.Error(w, ErrReplayed.Error(), http.StatusConflict)
+				return
+			}
+		}
+
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			if reserved {
+				cfg.seenCache.Forget(claims.JTI)
+			}
+			http.Error(w, "webhook: could not decode event", http.StatusBadRequest)
+			return
+		}
+
+		if err := dispatch(r.Context(), event); err != nil {
+			if reserved {
+				cfg.seenCache.Forget(claims.JTI)
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// Verify checks rawBody and header against MessageBird's JWS webhook
+// signature using the default clock skew. It is for callers who are not
+// using net/http, e.g. a different router's middleware.
+func Verify(rawBody []byte, header http.Header, url string, key []byte) error {
+	pub, err := parsePublicKey(string(key))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	_, err = verify(rawBody, header, url, pub, defaultClockSkew)
+	return err
+}
+
+// claims holds the subset of the JWS payload Verify checks.
+type claims struct {
+	JTI         string `json:"jti"`
+	IssuedAt    int64  `json:"iat"`
+	NotBefore   int64  `json:"nbf"`
+	ExpiresAt   int64  `json:"exp"`
+	URLHash     string `json:"url_hash"`
+	PayloadHash string `json:"payload_hash"`
+}
+
+func verify(rawBody []byte, header http.Header, url string, key *ecdsa.PublicKey, clockSkew time.Duration) (*claims, error) {
+	jws := header.Get(SignatureHeader)
+	if jws == "" {
+		return nil, ErrMissingSignature
+	}
+
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidSignature
+	}
+
+	signingInput := parts[0] + "." + parts[1]
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	if len(signature) != 64 {
+		return nil, ErrInvalidSignature
+	}
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(key, digest[:], r, s) {
+		return nil, ErrInvalidSignature
+	}
+
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	var c claims
+	if err := json.Unmarshal(payloadJSON, &c); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	now := time.Now()
+	if c.ExpiresAt != 0 && now.After(time.Unix(c.ExpiresAt, 0).Add(clockSkew)) {
+		return nil, ErrExpired
+	}
+	if c.NotBefore != 0 && now.Before(time.Unix(c.NotBefore, 0).Add(-clockSkew)) {
+		return nil, ErrExpired
+	}
+	if c.IssuedAt != 0 && now.Before(time.Unix(c.IssuedAt, 0).Add(-clockSkew)) {
+		return nil, ErrExpired
+	}
+
+	if !constantTimeHashEqual(c.URLHash, url) {
+		return nil, ErrURLMismatch
+	}
+	if !constantTimeHashEqual(c.PayloadHash, string(rawBody)) {
+		return nil, ErrPayloadMismatch
+	}
+
+	return &c, nil
+}
+
+func constantTimeHashEqual(wantHashHex string, data string) bool {
+	sum := sha256.Sum256([]byte(data))
+	want, err := base64.RawURLEncoding.DecodeString(wantHashHex)
+	if err != nil || len(want) != len(sum) {
+		// MessageBird documents url_hash/payload_hash as base64url, but fall
+		// back to hex in case a future version switches encodings.
+		return subtle.ConstantTimeCompare([]byte(fmt.Sprintf("%x", sum)), []byte(wantHashHex)) == 1
+	}
+	return subtle.ConstantTimeCompare(want, sum[:]) == 1
+}
+
+// parsePublicKey accepts a PEM-encoded ECDSA public key, as provided by
+// MessageBird for a given signing key.
+func parsePublicKey(signingKey string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(signingKey))
+	if block == nil {
+		return nil, errors.New("webhook: signing key is not PEM-encoded")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: could not parse signing key: %w", err)
+	}
+	ecKey, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("webhook: signing key is not an ECDSA public key")
+	}
+	return ecKey, nil
+}
+
+// requestURL reconstructs the full URL MessageBird signed, since url_hash
+// covers scheme, host and path.
+func requestURL(r *http.Request) string {
+	scheme := "https"
+	if r.TLS == nil && r.Header.Get("X-Forwarded-Proto") != "https" {
+		scheme = "http"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
@@ -0,0 +1,376 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func generateSigningKey(t *testing.T) (priv *ecdsa.PrivateKey, pemPub string) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	block := &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	return priv, string(pem.EncodeToMemory(block))
+}
+
+func sign(t *testing.T, priv *ecdsa.PrivateKey, url string, body []byte) string {
+	t.Helper()
+
+	urlHash := sha256.Sum256([]byte(url))
+	payloadHash := sha256.Sum256(body)
+
+	return signClaims(t, priv, claims{
+		JTI:         "test-jti",
+		IssuedAt:    time.Now().Unix(),
+		ExpiresAt:   time.Now().Add(time.Minute).Unix(),
+		URLHash:     base64.RawURLEncoding.EncodeToString(urlHash[:]),
+		PayloadHash: base64.RawURLEncoding.EncodeToString(payloadHash[:]),
+	})
+}
+
+// signClaims signs an arbitrary claims struct, letting tests construct
+// deliberately invalid tokens (expired, wrong hashes, etc).
+func signClaims(t *testing.T, priv *ecdsa.PrivateKey, c claims) string {
+	t.Helper()
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256","typ":"JWT"}`))
+	payloadJSON, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(payloadJSON)
+
+	signingInput := header + "." + payload
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestHandlerAcceptsValidSignature(t *testing.T) {
+	priv, pubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{"id":"msg-1"}}`)
+	url := "https://example.com/webhooks/messagebird"
+
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Host = "example.com"
+	req.Header.Set(SignatureHeader, sign(t, priv, url, body))
+
+	var got Event
+	dispatched := false
+	handler := Handler(pubPEM, func(_ context.Context, event Event) error {
+		dispatched = true
+		got = event
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !dispatched {
+		t.Fatal("expected dispatch to be called")
+	}
+	if got.ID != "evt-1" || got.Type != EventMessageReceived {
+		t.Fatalf("unexpected event: %+v", got)
+	}
+}
+
+func TestHandlerRejectsInvalidSignature(t *testing.T) {
+	_, pubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{}}`)
+	url := "https://example.com/webhooks/messagebird"
+
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Host = "example.com"
+	req.Header.Set(SignatureHeader, "not.a.signature")
+
+	handler := Handler(pubPEM, func(context.Context, Event) error {
+		t.Fatal("dispatch should not be called for an invalid signature")
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestHandlerRejectsReplayedToken(t *testing.T) {
+	priv, pubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{}}`)
+	url := "https://example.com/webhooks/messagebird"
+	jws := sign(t, priv, url, body)
+
+	dispatchCount := 0
+	handler := Handler(pubPEM, func(context.Context, Event) error {
+		dispatchCount++
+		return nil
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		req.Host = "example.com"
+		req.Header.Set(SignatureHeader, jws)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if i == 0 && rec.Code != http.StatusNoContent {
+			t.Fatalf("first delivery: expected 204, got %d", rec.Code)
+		}
+		if i == 1 && rec.Code != http.StatusConflict {
+			t.Fatalf("replayed delivery: expected 409, got %d", rec.Code)
+		}
+	}
+
+	if dispatchCount != 1 {
+		t.Fatalf("expected dispatch to run once, ran %d times", dispatchCount)
+	}
+}
+
+func TestHandlerRetriesAfterFailedDispatch(t *testing.T) {
+	priv, pubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{}}`)
+	url := "https://example.com/webhooks/messagebird"
+	jws := sign(t, priv, url, body)
+
+	dispatchCount := 0
+	handler := Handler(pubPEM, func(context.Context, Event) error {
+		dispatchCount++
+		if dispatchCount == 1 {
+			return errors.New("transient failure")
+		}
+		return nil
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		req.Host = "example.com"
+		req.Header.Set(SignatureHeader, jws)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if i == 0 && rec.Code != http.StatusInternalServerError {
+			t.Fatalf("first delivery: expected 500, got %d", rec.Code)
+		}
+		if i == 1 && rec.Code != http.StatusNoContent {
+			t.Fatalf("redelivery after a failed dispatch: expected 204, got %d", rec.Code)
+		}
+	}
+
+	if dispatchCount != 2 {
+		t.Fatalf("expected dispatch to run twice (original + redelivery), ran %d times", dispatchCount)
+	}
+}
+
+func TestHandlerRejectsExpiredToken(t *testing.T) {
+	priv, pubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{}}`)
+	url := "https://example.com/webhooks/messagebird"
+
+	urlHash := sha256.Sum256([]byte(url))
+	payloadHash := sha256.Sum256(body)
+	jws := signClaims(t, priv, claims{
+		JTI:         "expired-jti",
+		IssuedAt:    time.Now().Add(-2 * time.Hour).Unix(),
+		ExpiresAt:   time.Now().Add(-time.Hour).Unix(),
+		URLHash:     base64.RawURLEncoding.EncodeToString(urlHash[:]),
+		PayloadHash: base64.RawURLEncoding.EncodeToString(payloadHash[:]),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Host = "example.com"
+	req.Header.Set(SignatureHeader, jws)
+
+	handler := Handler(pubPEM, func(context.Context, Event) error {
+		t.Fatal("dispatch should not be called for an expired token")
+		return nil
+	}, WithClockSkew(0))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandlerRejectsNotYetValidToken(t *testing.T) {
+	priv, pubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{}}`)
+	url := "https://example.com/webhooks/messagebird"
+
+	urlHash := sha256.Sum256([]byte(url))
+	payloadHash := sha256.Sum256(body)
+	jws := signClaims(t, priv, claims{
+		JTI:         "future-jti",
+		IssuedAt:    time.Now().Add(time.Hour).Unix(),
+		NotBefore:   time.Now().Add(time.Hour).Unix(),
+		ExpiresAt:   time.Now().Add(2 * time.Hour).Unix(),
+		URLHash:     base64.RawURLEncoding.EncodeToString(urlHash[:]),
+		PayloadHash: base64.RawURLEncoding.EncodeToString(payloadHash[:]),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Host = "example.com"
+	req.Header.Set(SignatureHeader, jws)
+
+	handler := Handler(pubPEM, func(context.Context, Event) error {
+		t.Fatal("dispatch should not be called for a not-yet-valid token")
+		return nil
+	}, WithClockSkew(0))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandlerRejectsURLHashMismatch(t *testing.T) {
+	priv, pubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{}}`)
+	url := "https://example.com/webhooks/messagebird"
+
+	wrongURLHash := sha256.Sum256([]byte("https://example.com/somewhere-else"))
+	payloadHash := sha256.Sum256(body)
+	jws := signClaims(t, priv, claims{
+		JTI:         "url-mismatch-jti",
+		IssuedAt:    time.Now().Unix(),
+		ExpiresAt:   time.Now().Add(time.Minute).Unix(),
+		URLHash:     base64.RawURLEncoding.EncodeToString(wrongURLHash[:]),
+		PayloadHash: base64.RawURLEncoding.EncodeToString(payloadHash[:]),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Host = "example.com"
+	req.Header.Set(SignatureHeader, jws)
+
+	handler := Handler(pubPEM, func(context.Context, Event) error {
+		t.Fatal("dispatch should not be called when url_hash does not match")
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandlerRejectsPayloadHashMismatch(t *testing.T) {
+	priv, pubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{}}`)
+	url := "https://example.com/webhooks/messagebird"
+
+	urlHash := sha256.Sum256([]byte(url))
+	wrongPayloadHash := sha256.Sum256([]byte(`{"tampered":true}`))
+	jws := signClaims(t, priv, claims{
+		JTI:         "payload-mismatch-jti",
+		IssuedAt:    time.Now().Unix(),
+		ExpiresAt:   time.Now().Add(time.Minute).Unix(),
+		URLHash:     base64.RawURLEncoding.EncodeToString(urlHash[:]),
+		PayloadHash: base64.RawURLEncoding.EncodeToString(wrongPayloadHash[:]),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Host = "example.com"
+	req.Header.Set(SignatureHeader, jws)
+
+	handler := Handler(pubPEM, func(context.Context, Event) error {
+		t.Fatal("dispatch should not be called when payload_hash does not match")
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestVerifyAcceptsValidSignature(t *testing.T) {
+	priv, pubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{}}`)
+	url := "https://example.com/webhooks/messagebird"
+
+	header := http.Header{}
+	header.Set(SignatureHeader, sign(t, priv, url, body))
+
+	if err := Verify(body, header, url, []byte(pubPEM)); err != nil {
+		t.Fatalf("expected Verify to succeed, got: %v", err)
+	}
+}
+
+func TestVerifyRejectsTamperedBody(t *testing.T) {
+	priv, pubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{}}`)
+	url := "https://example.com/webhooks/messagebird"
+
+	header := http.Header{}
+	header.Set(SignatureHeader, sign(t, priv, url, body))
+
+	tampered := []byte(`{"id":"evt-1","type":"message.received","payload":{"tampered":true}}`)
+	if err := Verify(tampered, header, url, []byte(pubPEM)); err == nil {
+		t.Fatal("expected Verify to reject a tampered body")
+	}
+}
+
+func TestVerifyRejectsWrongSigningKey(t *testing.T) {
+	priv, _ := generateSigningKey(t)
+	_, otherPubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{}}`)
+	url := "https://example.com/webhooks/messagebird"
+
+	header := http.Header{}
+	header.Set(SignatureHeader, sign(t, priv, url, body))
+
+	if err := Verify(body, header, url, []byte(otherPubPEM)); err == nil {
+		t.Fatal("expected Verify to reject a signature from a different key")
+	}
+}
+
+func TestVerifyRejectsMissingSignatureHeader(t *testing.T) {
+	_, pubPEM := generateSigningKey(t)
+	body := []byte(`{"id":"evt-1","type":"message.received","payload":{}}`)
+
+	err := Verify(body, http.Header{}, "https://example.com/webhooks/messagebird", []byte(pubPEM))
+	if !errors.Is(err, ErrMissingSignature) {
+		t.Fatalf("expected ErrMissingSignature, got: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+)
+
+// SeenCache tracks webhook JWT IDs (jti) that have already been processed,
+// so a handler can reject replayed deliveries. Implementations must be
+// safe for concurrent use.
+type SeenCache interface {
+	// SeenRecently reports whether jti was already recorded within window,
+	// and if not, reserves it (recording it as seen for that window) so a
+	// concurrent delivery of the same jti is rejected as a replay.
+	SeenRecently(jti string, window time.Duration) bool
+	// Forget removes jti's reservation. Callers use this to release a jti
+	// reserved by SeenRecently when processing it failed, so MessageBird's
+	// at-least-once redelivery of the same event is not mistaken for a
+	// replay.
+	Forget(jti string)
+}
+
+// memorySeenCache is a SeenCache backed by an in-memory map. It is the
+// default used by Handler when no SeenCache is supplied, and is only
+// suitable for a single-process deployment.
+type memorySeenCache struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewMemorySeenCache returns a SeenCache backed by an in-memory map.
+func NewMemorySeenCache() SeenCache {
+	return &memorySeenCache{seen: make(map[string]time.Time)}
+}
+
+func (c *memorySeenCache) SeenRecently(jti string, window time.Duration) bool {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, expiresAt := range c.seen {
+		if now.After(expiresAt) {
+			delete(c.seen, id)
+		}
+	}
+
+	if expiresAt, ok := c.seen[jti]; ok && now.Before(expiresAt) {
+		return true
+	}
+	c.seen[jti] = now.Add(window)
+	return false
+}
+
+func (c *memorySeenCache) Forget(jti string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.seen, jti)
+}